@@ -0,0 +1,94 @@
+package shellyrpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+)
+
+// authRequiredCode is the RPCError code a Shelly device responds with when
+// the called method requires authentication.
+const authRequiredCode = 401
+
+// RPCError is returned when the device's response contains an "error"
+// object instead of a "result". A Code of authRequiredCode means the
+// method requires authentication, see Client.Username and Client.Password.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// authChallenge is the JSON payload carried in an authRequiredCode
+// RPCError's Message, describing the realm and nonce to answer.
+type authChallenge struct {
+	Realm string `json:"realm"`
+	Nonce uint64 `json:"nonce"`
+}
+
+// auth is attached to a RequestFrame's "auth" field in answer to an
+// authChallenge.
+type auth struct {
+	Realm    string `json:"realm"`
+	Username string `json:"username"`
+	Nonce    uint64 `json:"nonce"`
+	Cnonce   uint64 `json:"cnonce"`
+	Nc       int    `json:"nc"`
+	Response string `json:"response"`
+}
+
+// computeAuth answers a fresh challenge from the device, caching its realm
+// and nonce so that cachedAuth can answer further calls without waiting for
+// another 401.
+func (r *Client) computeAuth(challenge authChallenge) auth {
+	r.authMu.Lock()
+	r.authRealm = challenge.Realm
+	r.authNonce = challenge.Nonce
+	r.authNc = 1
+	nc := r.authNc
+	r.authMu.Unlock()
+
+	return r.buildAuth(challenge.Realm, challenge.Nonce, nc)
+}
+
+// cachedAuth answers using a realm/nonce obtained from an earlier
+// computeAuth, avoiding a second round-trip for every call once the client
+// has authenticated once.
+func (r *Client) cachedAuth() (auth, bool) {
+	r.authMu.Lock()
+	if r.authRealm == "" {
+		r.authMu.Unlock()
+		return auth{}, false
+	}
+	r.authNc++
+	realm, nonce, nc := r.authRealm, r.authNonce, r.authNc
+	r.authMu.Unlock()
+
+	return r.buildAuth(realm, nonce, nc), true
+}
+
+func (r *Client) buildAuth(realm string, nonce uint64, nc int) auth {
+	cnonce := rand.Uint64()
+
+	ha1 := sha256Hex(fmt.Sprintf("%s:%s:%s", r.Username, realm, r.Password))
+	ha2 := sha256Hex(fmt.Sprintf("dummy_method:dummy_uri:%s", sha256Hex("")))
+	response := sha256Hex(fmt.Sprintf("%s:%d:%d:%d:auth:%s", ha1, nonce, nc, cnonce, ha2))
+
+	return auth{
+		Realm:    realm,
+		Username: r.Username,
+		Nonce:    nonce,
+		Cnonce:   cnonce,
+		Nc:       nc,
+		Response: response,
+	}
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
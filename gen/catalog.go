@@ -0,0 +1,47 @@
+// Package gen parses the Shelly RPC method catalog and generates strongly
+// typed Go wrappers for it, so that callers no longer hand-marshal
+// map[string]any params and interpret untyped results.
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Field is a single parameter or result field of a Method.
+type Field struct {
+	Name string `json:"name"`
+	// Type is one of: string, int, float, bool.
+	Type string `json:"type"`
+}
+
+// Method describes one RPC method exposed by a Component, e.g. "SetTarget"
+// on "BluTRV".
+type Method struct {
+	Name   string  `json:"name"`
+	Params []Field `json:"params"`
+	Result []Field `json:"result"`
+}
+
+// Component groups the RPC methods of a single Shelly component, e.g.
+// "BluTRV" or "Switch".
+type Component struct {
+	Name    string   `json:"name"`
+	Methods []Method `json:"methods"`
+}
+
+// Catalog is the top-level Shelly RPC method catalog that Generate reads
+// from, typically exported from Shelly.ListMethods or hand-written for the
+// components a project cares about.
+type Catalog struct {
+	Components []Component `json:"components"`
+}
+
+// Parse reads a Catalog from its JSON representation.
+func Parse(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("unmarshal catalog: %w", err)
+	}
+	return &catalog, nil
+}
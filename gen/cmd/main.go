@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hoegaarden/go-shellyrpc/gen"
+)
+
+// Reads a method catalog JSON file and writes generated, strongly typed Go
+// wrappers for it. Invoked via the //go:generate directive in
+// components_gen.go.
+func main() {
+	var schemaPath, outPath, pkg, shellyrpcImport string
+
+	flag.StringVar(&schemaPath, "schema", "schema.json", "path to the method catalog JSON file")
+	flag.StringVar(&outPath, "out", "components_gen.go", "path to write the generated Go source to")
+	flag.StringVar(&pkg, "package", "shellyrpc", "package name for the generated source")
+	flag.StringVar(&shellyrpcImport, "shellyrpc-import", "", "import path of the shellyrpc root package, to reference its Client type from a different package; leave empty when generating into the shellyrpc package itself")
+
+	flag.Parse()
+
+	os.Exit(run(schemaPath, outPath, pkg, shellyrpcImport))
+}
+
+func run(schemaPath, outPath, pkg, shellyrpcImport string) int {
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		log.Printf("Failed to read schema %q: %v", schemaPath, err)
+		return 10
+	}
+
+	catalog, err := gen.Parse(data)
+	if err != nil {
+		log.Printf("Failed to parse catalog: %v", err)
+		return 20
+	}
+
+	directive := fmt.Sprintf("go:generate go run ./gen/cmd -schema %s -out %s -package %s -shellyrpc-import %q", schemaPath, outPath, pkg, shellyrpcImport)
+
+	src, err := gen.Generate(catalog, pkg, shellyrpcImport, directive)
+	if err != nil {
+		log.Printf("Failed to generate bindings: %v", err)
+		return 30
+	}
+
+	if err := os.WriteFile(outPath, src, 0o644); err != nil {
+		log.Printf("Failed to write %q: %v", outPath, err)
+		return 40
+	}
+
+	return 0
+}
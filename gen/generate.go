@@ -0,0 +1,132 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"unicode"
+)
+
+// Generate renders Go source binding every method in catalog to a typed
+// wrapper, in package pkg. If shellyrpcImport is non-empty it is imported
+// as the root package and Client is referenced through it; pass "" when
+// generating straight into the shellyrpc package itself (as the shipped
+// components_gen.go does), to avoid a self-import. directive, if non-empty,
+// is emitted verbatim as a "//go:generate ..." comment (without the leading
+// "//") so the output carries its own regeneration command forward; pass
+// the same invocation the caller used to produce this run.
+func Generate(catalog *Catalog, pkg, shellyrpcImport, directive string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	qualifier := ""
+	if shellyrpcImport != "" {
+		qualifier = "shellyrpc."
+	}
+	clientType := qualifier + "Client"
+
+	fmt.Fprintf(&buf, "// Code generated by shellyrpc/gen from the RPC method catalog. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	if shellyrpcImport != "" {
+		fmt.Fprintf(&buf, "import (\n\t\"encoding/json\"\n\t\"fmt\"\n\n\tshellyrpc %q\n)\n\n", shellyrpcImport)
+	} else {
+		fmt.Fprintf(&buf, "import (\n\t\"fmt\"\n)\n\n")
+	}
+	if directive != "" {
+		fmt.Fprintf(&buf, "//%s\n\n", directive)
+	}
+	if shellyrpcImport != "" {
+		// toParams and fromResult are unexported in package shellyrpc, so
+		// output for another package carries its own copies.
+		fmt.Fprintf(&buf, "func toParams(v any) (map[string]any, error) {\n\traw, err := json.Marshal(v)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\n\tparams := map[string]any{}\n\terr = json.Unmarshal(raw, &params)\n\treturn params, err\n}\n\n")
+		fmt.Fprintf(&buf, "func fromResult(res map[string]any, out any) error {\n\traw, err := json.Marshal(res)\n\tif err != nil {\n\t\treturn err\n\t}\n\n\treturn json.Unmarshal(raw, out)\n}\n\n")
+	}
+
+	for _, comp := range catalog.Components {
+		fmt.Fprintf(&buf, "// %s binds the RPC methods of the Shelly %q component.\n", comp.Name, comp.Name)
+		fmt.Fprintf(&buf, "var %s %sComponent\n\n", comp.Name, comp.Name)
+		fmt.Fprintf(&buf, "type %sComponent struct{}\n\n", comp.Name)
+
+		for _, m := range comp.Methods {
+			paramsType := comp.Name + m.Name + "Params"
+			resultType := comp.Name + m.Name + "Result"
+
+			writeStruct(&buf, paramsType, m.Params)
+			writeStruct(&buf, resultType, m.Result)
+
+			fmt.Fprintf(&buf, "// Call%s calls %s.%s on client and decodes its result.\n", m.Name, comp.Name, m.Name)
+			fmt.Fprintf(&buf, "func (c %sComponent) Call%s(client *%s, params %s) (%s, error) {\n", comp.Name, m.Name, clientType, paramsType, resultType)
+			fmt.Fprintf(&buf, "\tvar result %s\n\n", resultType)
+			fmt.Fprintf(&buf, "\traw, err := toParams(params)\n")
+			fmt.Fprintf(&buf, "\tif err != nil {\n\t\treturn result, fmt.Errorf(\"encode params: %%w\", err)\n\t}\n\n")
+			fmt.Fprintf(&buf, "\tres, err := client.Call(%q, raw)\n", comp.Name+"."+m.Name)
+			fmt.Fprintf(&buf, "\tif err != nil {\n\t\treturn result, err\n\t}\n\n")
+			fmt.Fprintf(&buf, "\terr = fromResult(res, &result)\n")
+			fmt.Fprintf(&buf, "\treturn result, err\n")
+			fmt.Fprintf(&buf, "}\n\n")
+		}
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w", err)
+	}
+
+	return formatted, nil
+}
+
+func writeStruct(buf *bytes.Buffer, name string, fields []Field) {
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", exportedName(f.Name), goType(f.Type), f.Name)
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+// exportedName turns a schema field name into an exported Go identifier,
+// camel-casing on "_" and letter/digit boundaries: "fw_id" -> "FwId",
+// "target_C" -> "TargetC", "current_pos" -> "CurrentPos".
+func exportedName(name string) string {
+	var b []rune
+
+	upperNext := true
+	var prev rune
+
+	for _, r := range name {
+		if r == '_' {
+			upperNext = true
+			prev = r
+			continue
+		}
+
+		if prev != 0 && prev != '_' && isDigit(r) != isDigit(prev) {
+			upperNext = true
+		}
+
+		if upperNext {
+			b = append(b, unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			b = append(b, r)
+		}
+		prev = r
+	}
+
+	return string(b)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func goType(t string) string {
+	switch t {
+	case "int":
+		return "int"
+	case "float":
+		return "float64"
+	case "bool":
+		return "bool"
+	default:
+		return "string"
+	}
+}
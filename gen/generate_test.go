@@ -0,0 +1,41 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateMatchesCheckedInOutput guards against the generator and the
+// checked-in components_gen.go drifting apart: it reruns the exact
+// invocation recorded in that file's own //go:generate directive and diffs
+// byte for byte, so a change to Generate that would silently reformat or
+// rename fields on the next `go generate ./...` is caught here instead of
+// by a maintainer running it by hand.
+func TestGenerateMatchesCheckedInOutput(t *testing.T) {
+	schema, err := os.ReadFile("schema.json")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+
+	catalog, err := Parse(schema)
+	if err != nil {
+		t.Fatalf("parse catalog: %v", err)
+	}
+
+	directive := `go:generate go run ./gen/cmd -schema gen/schema.json -out components_gen.go -package shellyrpc -shellyrpc-import ""`
+
+	got, err := Generate(catalog, "shellyrpc", "", directive)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	want, err := os.ReadFile(filepath.Join("..", "components_gen.go"))
+	if err != nil {
+		t.Fatalf("read checked-in components_gen.go: %v", err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("Generate(catalog) does not match the checked-in components_gen.go; run `go generate ./...` and commit the result")
+	}
+}
@@ -0,0 +1,64 @@
+package shellyrpc
+
+import (
+	"path"
+	"sync"
+)
+
+// NotificationFrame is an asynchronous frame sent by the device that is not
+// a response to a pending Call, e.g. NotifyStatus or NotifyEvent. It is
+// delivered to handlers registered via EnableNotifications.
+type NotificationFrame struct {
+	Source string `json:"src"`
+	Method string `json:"method"`
+	Params Params `json:"params"`
+}
+
+type notificationSubscription struct {
+	pattern string
+	handler func(NotificationFrame)
+}
+
+// notificationRegistry dispatches decoded NotificationFrames to handlers
+// subscribed via a method-name glob, as matched by path.Match.
+type notificationRegistry struct {
+	mu   sync.Mutex
+	subs []notificationSubscription
+}
+
+func (n *notificationRegistry) add(pattern string, handler func(NotificationFrame)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subs = append(n.subs, notificationSubscription{pattern: pattern, handler: handler})
+}
+
+// dispatch runs each matching handler on its own goroutine, never inline on
+// the caller. It is called from the pump goroutine, which is also the sole
+// reader of r.calls; a handler that turns around and calls Call/CallContext
+// (the natural way to react to e.g. NotifyStatus) would deadlock waiting to
+// send on r.calls if it ran synchronously here.
+func (n *notificationRegistry) dispatch(frame NotificationFrame) {
+	n.mu.Lock()
+	subs := make([]notificationSubscription, len(n.subs))
+	copy(subs, n.subs)
+	n.mu.Unlock()
+
+	for _, sub := range subs {
+		if ok, _ := path.Match(sub.pattern, frame.Method); ok {
+			go sub.handler(frame)
+		}
+	}
+}
+
+// EnableNotifications subscribes handler to notification frames whose
+// Method matches pattern (a path.Match glob, e.g. "NotifyStatus" or
+// "Notify*"). It can be called multiple times to register several handlers.
+//
+// The data characteristic's notifications are subscribed to once, by Setup;
+// the pump goroutine demuxes what arrives on it between pending calls and
+// notifications, so registering a handler here never races with an
+// in-flight Call/Roundtrip. Frames that answer a pending call are never
+// passed to handler.
+func (r *Client) EnableNotifications(pattern string, handler func(NotificationFrame)) {
+	r.notifications.add(pattern, handler)
+}
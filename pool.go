@@ -0,0 +1,166 @@
+package shellyrpc
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Pool manages up to Size concurrently-connected Clients, keyed by address,
+// evicting the least-recently-used connection to make room when it is full.
+// Shelly BLU devices accept only a small number of simultaneous BLE
+// connections, so a process talking to several of them should share a Pool
+// rather than keeping every Client connected at once.
+type Pool struct {
+	// Size is the maximum number of connections held open at once.
+	// optional, defaults to 1.
+	Size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// poolEntry reserves address's slot in the pool while its Client connects,
+// so that Get for a different address is not blocked behind a single slow
+// connect. ready is closed once client/err are final; a concurrent Get for
+// the same address waits on it instead of starting a second connection.
+type poolEntry struct {
+	address string
+	client  *Client
+	err     error
+	ready   chan struct{}
+}
+
+func (p *Pool) init() {
+	if p.order != nil {
+		return
+	}
+	if p.Size <= 0 {
+		p.Size = 1
+	}
+	p.entries = map[string]*list.Element{}
+	p.order = list.New()
+}
+
+// Get returns a connected Client for address, reusing the existing
+// connection if the pool already holds one. If the pool is full, the
+// least-recently-used connection is torn down to make room. newClient is
+// called, with Address left unset, to construct a Client when a new
+// connection is needed; Setup is called on it before it is returned.
+//
+// The slot for address, and the victim to evict, are both decided under
+// the pool lock, but the victim's Teardown and the new Client's Setup both
+// run without it held, so Get for other addresses can proceed concurrently
+// instead of queuing behind one connect or disconnect.
+func (p *Pool) Get(address string, newClient func() *Client) (*Client, error) {
+	p.mu.Lock()
+
+	p.init()
+
+	if el, ok := p.entries[address]; ok {
+		entry := el.Value.(*poolEntry)
+		p.order.MoveToFront(el)
+		p.mu.Unlock()
+
+		<-entry.ready
+		return entry.client, entry.err
+	}
+
+	var victim *poolEntry
+	if p.order.Len() >= p.Size {
+		victim = p.reserveEvictionLocked()
+	}
+
+	entry := &poolEntry{address: address, ready: make(chan struct{})}
+	el := p.order.PushFront(entry)
+	p.entries[address] = el
+
+	p.mu.Unlock()
+
+	if victim != nil && victim.client != nil {
+		if err := victim.client.Teardown(); err != nil {
+			teardownErr := fmt.Errorf("evict %s: teardown: %w", victim.address, err)
+
+			p.mu.Lock()
+			p.order.Remove(el)
+			delete(p.entries, address)
+			entry.err = teardownErr
+			p.mu.Unlock()
+
+			close(entry.ready)
+			return nil, entry.err
+		}
+	}
+
+	client := newClient()
+	client.Address = address
+	err := client.Setup()
+
+	p.mu.Lock()
+	if err != nil {
+		p.order.Remove(el)
+		delete(p.entries, address)
+		entry.err = fmt.Errorf("setup %s: %w", address, err)
+	} else {
+		entry.client = client
+	}
+	p.mu.Unlock()
+
+	close(entry.ready)
+
+	return entry.client, entry.err
+}
+
+// reserveEvictionLocked removes the least-recently-used connection that has
+// finished connecting from the pool's bookkeeping and returns it, so its
+// Client can be torn down by the caller without the pool lock held. Entries
+// still mid-Setup are left in place, since evicting one here would race its
+// own Get call committing it; if every entry is still connecting, Get is
+// allowed to momentarily exceed Size rather than corrupt pool state.
+func (p *Pool) reserveEvictionLocked() *poolEntry {
+	for el := p.order.Back(); el != nil; el = el.Prev() {
+		entry := el.Value.(*poolEntry)
+
+		select {
+		case <-entry.ready:
+		default:
+			continue
+		}
+
+		p.order.Remove(el)
+		delete(p.entries, entry.address)
+
+		return entry
+	}
+
+	return nil
+}
+
+// Close tears down every connection currently held by the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.init()
+
+	entries := make([]*poolEntry, 0, p.order.Len())
+	for el := p.order.Front(); el != nil; el = el.Next() {
+		entries = append(entries, el.Value.(*poolEntry))
+	}
+
+	p.entries = map[string]*list.Element{}
+	p.order = list.New()
+	p.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		<-entry.ready
+		if entry.client != nil {
+			if err := entry.client.Teardown(); err != nil {
+				errs = append(errs, fmt.Errorf("teardown %s: %w", entry.address, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,179 @@
+package shellyrpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tinygo.org/x/bluetooth"
+)
+
+type callRequest struct {
+	frame  RequestFrame
+	result chan<- callResult
+}
+
+type callResult struct {
+	frame ResponseFrame
+	err   error
+}
+
+// incomingFrame is the union of everything the device can send back on the
+// data characteristic: either the response to a pending call (ID/
+// Destination/Result/Error set, Method empty) or an asynchronous
+// notification (Method set). The pump demuxes on whether ID matches a
+// pending call.
+type incomingFrame struct {
+	ID          uint64    `json:"id"`
+	Destination string    `json:"dst"`
+	Result      Result    `json:"result"`
+	Error       *RPCError `json:"error,omitempty"`
+	Source      string    `json:"src"`
+	Method      string    `json:"method"`
+	Params      Params    `json:"params"`
+}
+
+// pump is the single goroutine that owns the characteristics. It serializes
+// outgoing writes, and demuxes every frame the device sends back on the
+// data characteristic: a frame whose ID matches a pending call is routed to
+// the caller waiting on that Roundtrip/Call, everything else is handed to
+// the notification registry. Started by Setup, stopped by Teardown.
+//
+// A single frame is rarely delivered in one notification: tinygo's
+// EnableNotifications callback fires once per BLE "value changed" event,
+// which carries at most one ATT MTU, while responses and notifications from
+// the device are routinely larger than that. Every frame is still preceded
+// by its length on the RX control characteristic, exactly as in the
+// blocking-Read baseline this replaced, so the pump reads that length once
+// per frame and accumulates dataIncoming chunks up to it before decoding.
+func (r *Client) pump() {
+	pending := map[uint64]chan<- callResult{}
+
+	var buf []byte
+	var frameLen int
+
+	for {
+		select {
+		case <-r.pumpDone:
+			return
+
+		case call := <-r.calls:
+			if err := r.sendRequest(call.frame); err != nil {
+				call.result <- callResult{err: fmt.Errorf("send request: %w", err)}
+				continue
+			}
+			pending[call.frame.ID] = call.result
+
+		case chunk := <-r.dataIncoming:
+			if buf == nil {
+				length, err := readFromChar(r.rxCtrlChar, 4)
+				if err != nil {
+					continue
+				}
+				frameLen = int(fromBytes(length))
+			}
+
+			buf = append(buf, chunk...)
+			if len(buf) < frameLen {
+				continue
+			}
+
+			frameBytes := buf[:frameLen]
+			buf = nil
+
+			var frame incomingFrame
+			if err := json.Unmarshal(frameBytes, &frame); err != nil {
+				continue
+			}
+
+			if result, ok := pending[frame.ID]; ok {
+				delete(pending, frame.ID)
+
+				result <- callResult{frame: ResponseFrame{
+					ID:          frame.ID,
+					Destination: frame.Destination,
+					Result:      frame.Result,
+					Error:       frame.Error,
+				}}
+				continue
+			}
+
+			r.notifications.dispatch(NotificationFrame{
+				Source: frame.Source,
+				Method: frame.Method,
+				Params: frame.Params,
+			})
+		}
+	}
+}
+
+// readFromChar reads exactly length bytes from char via repeated blocking
+// Reads, chunked to its MTU. It is used for the RX control characteristic,
+// whose value is read synchronously rather than subscribed to.
+func readFromChar(char bluetooth.DeviceCharacteristic, length int) ([]byte, error) {
+	mtu, err := char.GetMTU()
+	if err != nil {
+		return nil, fmt.Errorf("get MTU: %w", err)
+	}
+
+	res := []byte{}
+
+	for length > 0 {
+		chunk := make([]byte, mtu)
+		n, err := char.Read(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("read from characteristic: %w", err)
+		}
+		res = append(res, chunk[:n]...)
+		length -= n
+	}
+
+	return res, nil
+}
+
+// sendRequest marshals req and writes it to the device. It must only ever
+// be called from the pump goroutine, which is the sole writer of the
+// characteristics; the response is delivered later, out of band, when it
+// arrives on dataIncoming.
+func (r *Client) sendRequest(req RequestFrame) error {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+	reqLenBytes := toBytes(uint32(len(reqBytes)))
+
+	if err := writeToChar(r.txCtrlChar, reqLenBytes); err != nil {
+		return fmt.Errorf("write request length to TX control characteristic: %w", err)
+	}
+
+	if err := writeToChar(r.dataChar, reqBytes); err != nil {
+		return fmt.Errorf("write request to data characteristic: %w", err)
+	}
+
+	return nil
+}
+
+func writeToChar(char bluetooth.DeviceCharacteristic, data []byte) error {
+	mtu, err := char.GetMTU()
+	if err != nil {
+		return fmt.Errorf("get MTU: %w", err)
+	}
+
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > int(mtu) {
+			chunk = chunk[:mtu]
+		}
+
+		n, err := char.WriteWithoutResponse(chunk)
+		if err != nil {
+			return fmt.Errorf("write chunk to characteristic: %w", err)
+		}
+		if n != len(chunk) {
+			return fmt.Errorf("write chunk to characteristic: wrote %d bytes, expected to write %d bytes", n, len(chunk))
+		}
+
+		data = data[len(chunk):]
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package shellyrpc
+
+import (
+	"context"
+	"fmt"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// Discovered describes a single advertisement seen during a Scan that
+// advertises the wanted service UUID.
+type Discovered struct {
+	Address   string
+	LocalName string
+	RSSI      int16
+}
+
+// ScanOptions configures Scan.
+type ScanOptions struct {
+	// Adapter is the local bluetooth adapter to use.
+	// optional, defaults to the default/first adapter
+	Adapter Adapter
+	// ServiceUUID is the UUID advertised devices must expose to be reported.
+	// optional, defaults to DefaultServiceUUID
+	ServiceUUID ServiceUUID
+}
+
+// Scan scans for nearby devices advertising opts.ServiceUUID and sends each
+// one on the returned channel as it is seen. Scanning, and the returned
+// channel, stop once ctx is done.
+func Scan(ctx context.Context, opts ScanOptions) (<-chan Discovered, error) {
+	adapter, err := opts.Adapter.Get()
+	if err != nil {
+		return nil, fmt.Errorf("get adapter: %w", err)
+	}
+
+	serviceUUID, err := opts.ServiceUUID.Get()
+	if err != nil {
+		return nil, fmt.Errorf("parse service UUID: %w", err)
+	}
+
+	found := make(chan Discovered)
+
+	go func() {
+		<-ctx.Done()
+		_ = adapter.StopScan()
+	}()
+
+	go func() {
+		defer close(found)
+
+		_ = adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
+			if !result.HasServiceUUID(serviceUUID) {
+				return
+			}
+
+			select {
+			case found <- Discovered{
+				Address:   result.Address.String(),
+				LocalName: result.LocalName(),
+				RSSI:      result.RSSI,
+			}:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	return found, nil
+}
+
+// SetupByName scans for a device whose advertised local name equals name,
+// stops scanning once it is found, and connects to it as Setup does. It is
+// a convenience for users who don't know their device's MAC address yet.
+func (r *Client) SetupByName(ctx context.Context, name string) error {
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found, err := Scan(scanCtx, ScanOptions{Adapter: r.Adapter, ServiceUUID: r.ServiceUUID})
+	if err != nil {
+		return fmt.Errorf("scan for device: %w", err)
+	}
+
+	for d := range found {
+		if d.LocalName != name {
+			continue
+		}
+		cancel()
+
+		r.Address = d.Address
+		return r.Setup()
+	}
+
+	return fmt.Errorf("no device named %q found", name)
+}
@@ -2,11 +2,14 @@ package shellyrpc
 
 import (
 	"cmp"
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	"tinygo.org/x/bluetooth"
@@ -41,12 +44,39 @@ type Client struct {
 	// optional
 	Timeout time.Duration
 
-	remoteDevice                     bluetooth.Device
-	dataChar, txCtrlChar, rxCtrlChar bluetooth.DeviceCharacteristic
+	// Username is the RPC user to authenticate as, if the device requires
+	// authentication.
+	// optional
+	Username string
+	// Password is the RPC password for Username.
+	// optional
+	Password string
+
+	authMu    sync.Mutex
+	authRealm string
+	authNonce uint64
+	authNc    int
+
+	remoteDevice bluetooth.Device
+	dataChar     bluetooth.DeviceCharacteristic
+	txCtrlChar   bluetooth.DeviceCharacteristic
+	// rxCtrlChar carries the byte length of the next incoming frame on
+	// dataChar; the pump reads it once per frame to know how many
+	// dataIncoming chunks to accumulate before decoding, see pump.go.
+	rxCtrlChar bluetooth.DeviceCharacteristic
+
+	notifications notificationRegistry
+
+	calls        chan callRequest
+	dataIncoming chan []byte
+	pumpDone     chan struct{}
+	teardownOnce sync.Once
 }
 
 // Setup connects to the remote device and sets up the necessary
-// characteristics to allow communication.
+// characteristics to allow communication. It also starts the background
+// pump goroutine that owns the characteristics, so that Call/CallContext
+// can be used safely from multiple goroutines.
 func (r *Client) Setup() (err error) {
 	if r.Address == "" {
 		return fmt.Errorf("Address is required")
@@ -115,11 +145,31 @@ func (r *Client) Setup() (err error) {
 	r.txCtrlChar = chars[1]
 	r.rxCtrlChar = chars[2]
 
+	r.calls = make(chan callRequest)
+	r.dataIncoming = make(chan []byte, 16)
+	r.pumpDone = make(chan struct{})
+
+	err = r.dataChar.EnableNotifications(func(buf []byte) {
+		frame := append([]byte(nil), buf...)
+		select {
+		case r.dataIncoming <- frame:
+		case <-r.pumpDone:
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("enable notifications on data characteristic: %w", err)
+	}
+
+	go r.pump()
+
 	return nil
 }
 
-// Teardown disconnects from the remote device.
+// Teardown stops the background pump and disconnects from the remote
+// device. It is safe to call more than once, e.g. from a defensive defer
+// alongside an explicit cleanup path; repeat calls just disconnect again.
 func (r *Client) Teardown() error {
+	r.teardownOnce.Do(func() { close(r.pumpDone) })
 	return r.remoteDevice.Disconnect()
 }
 
@@ -134,60 +184,80 @@ type RequestFrame struct {
 	Source string `json:"src"`
 	Method string `json:"method"`
 	Params Params `json:"params"`
+	Auth   *auth  `json:"auth,omitempty"`
 }
 
 type ResponseFrame struct {
-	ID          uint64 `json:"id"`
-	Destination string `json:"dst"`
-	Result      Result `json:"result"`
+	ID          uint64    `json:"id"`
+	Destination string    `json:"dst"`
+	Result      Result    `json:"result"`
+	Error       *RPCError `json:"error,omitempty"`
 }
 
 // Roundtrip sends the given request frame to the device and reads the response frame.
 // The high-level Call method should be preferred over this method, except when
 // access to the raw frames is needed.
 func (r *Client) Roundtrip(req RequestFrame) (ResponseFrame, error) {
-	res := ResponseFrame{}
+	return r.RoundtripContext(context.Background(), req)
+}
 
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return res, fmt.Errorf("marshalling request: %w", err)
+// RoundtripContext is Roundtrip with a context to cancel a stuck call. The
+// request is still submitted to the background pump and cannot be
+// interrupted once the pump has started the characteristic I/O for it, but
+// a caller that never gets a slot on the pump, or whose response never
+// arrives, is released when ctx is done.
+func (r *Client) RoundtripContext(ctx context.Context, req RequestFrame) (ResponseFrame, error) {
+	result := make(chan callResult, 1)
+
+	select {
+	case r.calls <- callRequest{frame: req, result: result}:
+	case <-r.pumpDone:
+		return ResponseFrame{}, fmt.Errorf("client is torn down")
+	case <-ctx.Done():
+		return ResponseFrame{}, ctx.Err()
 	}
-	reqLenBytes := toBytes(uint32(len(reqBytes)))
 
-	err = writeToChar(r.txCtrlChar, reqLenBytes)
-	if err != nil {
-		return res, fmt.Errorf("write request length to TX control characteristic: %w", err)
+	select {
+	case res := <-result:
+		return res.frame, res.err
+	case <-ctx.Done():
+		return ResponseFrame{}, ctx.Err()
 	}
+}
 
-	err = writeToChar(r.dataChar, reqBytes)
-	if err != nil {
-		return res, fmt.Errorf("write request to data characteristic: %w", err)
-	}
+// Call calls the given method with the given parameters and returns the result or an error.
+// It builds the request frame, sends it to the device, and reads the response
+// frame, it also checks for the correct ID and source to ensure the response
+// is for this request.
+func (r *Client) Call(method string, params Params) (Result, error) {
+	return r.CallContext(context.Background(), method, params)
+}
 
-	resLenBytes, err := readFromChar(r.rxCtrlChar, 4)
-	if err != nil {
-		return res, fmt.Errorf("read response length from RX control characteristic: %w", err)
+// CallContext is Call with a context to cancel a stuck RPC, see
+// RoundtripContext.
+func (r *Client) CallContext(ctx context.Context, method string, params Params) (Result, error) {
+	var reqAuth *auth
+	if r.Username != "" {
+		if a, ok := r.cachedAuth(); ok {
+			reqAuth = &a
+		}
 	}
-	resLen := int(fromBytes(resLenBytes))
 
-	resBytes, err := readFromChar(r.dataChar, resLen)
-	if err != nil {
-		return res, fmt.Errorf("read response from data characteristic: %w", err)
-	}
+	res, err := r.callOnce(ctx, method, params, reqAuth)
 
-	err = json.Unmarshal(resBytes, &res)
-	if err != nil {
-		return res, fmt.Errorf("unmarshal response: %w", err)
+	var rpcErr RPCError
+	if r.Username != "" && errors.As(err, &rpcErr) && rpcErr.Code == authRequiredCode {
+		var challenge authChallenge
+		if jsonErr := json.Unmarshal([]byte(rpcErr.Message), &challenge); jsonErr == nil {
+			a := r.computeAuth(challenge)
+			return r.callOnce(ctx, method, params, &a)
+		}
 	}
 
-	return res, nil
+	return res, err
 }
 
-// Call calls the given method with the given parameters and returns the result or an error.
-// It builds the request frame, sends it to the device, and reads the response
-// frame, it also checks for the correct ID and source to ensure the response
-// is for this request.
-func (r *Client) Call(method string, params Params) (Result, error) {
+func (r *Client) callOnce(ctx context.Context, method string, params Params, reqAuth *auth) (Result, error) {
 	// this is pseudo random, but should be good enough for this purpose
 	id := rand.Uint64()
 
@@ -196,9 +266,10 @@ func (r *Client) Call(method string, params Params) (Result, error) {
 		Source: SourceName,
 		Method: method,
 		Params: params,
+		Auth:   reqAuth,
 	}
 
-	res, err := r.Roundtrip(req)
+	res, err := r.RoundtripContext(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("roundtrip: %w", err)
 	}
@@ -209,69 +280,36 @@ func (r *Client) Call(method string, params Params) (Result, error) {
 	if e, a := SourceName, res.Destination; e != a {
 		return nil, fmt.Errorf("wrong response destination, expected: %s, got: %s", e, a)
 	}
-
-	return res.Result, nil
-}
-
-func readFromChar(char bluetooth.DeviceCharacteristic, length int) ([]byte, error) {
-	mtu, err := char.GetMTU()
-	if err != nil {
-		return nil, fmt.Errorf("get MTU: %w", err)
-	}
-
-	res := []byte{}
-
-	for length > 0 {
-		buf := make([]byte, mtu)
-		n, err := char.Read(buf)
-		if err != nil {
-			return nil, fmt.Errorf("read from characteristic: %w", err)
-		}
-		res = append(res, buf[:n]...)
-		length -= n
-	}
-
-	return res, nil
-}
-
-func writeToChar(char bluetooth.DeviceCharacteristic, data []byte) error {
-	mtu, err := char.GetMTU()
-	if err != nil {
-		return fmt.Errorf("get MTU: %w", err)
-	}
-
-	for len(data) > 0 {
-		chunk := data
-		if len(chunk) > int(mtu) {
-			chunk = chunk[:mtu]
-		}
-
-		n, err := char.WriteWithoutResponse(chunk)
-		if err != nil {
-			return fmt.Errorf("write chunk to characteristic: %w", err)
-		}
-		if n != len(chunk) {
-			return fmt.Errorf("write chunk to characteristic: wrote %d bytes, expected to write %d bytes", n, len(chunk))
-		}
-
-		data = data[len(chunk):]
+	if res.Error != nil {
+		return nil, *res.Error
 	}
 
-	return nil
+	return res.Result, nil
 }
 
 // Adapter is the local bluetooth adapter name to use.
 // optional, defaults to the default/first adapter ("hci0").
 type Adapter string
 
+// adapterOnce guards bluetooth.DefaultAdapter.Enable, which must only be
+// called once per process: multiple Clients (e.g. held by a Pool) share the
+// single enabled adapter rather than each enabling it.
+var (
+	adapterOnce sync.Once
+	adapterErr  error
+)
+
 func (a Adapter) Get() (*bluetooth.Adapter, error) {
 	if a != "" {
 		// https://github.com/tinygo-org/bluetooth/pull/303
 		return nil, fmt.Errorf("tinygo/bluetooth does not allow other adapters")
 	}
 	adapter := bluetooth.DefaultAdapter
-	if err := adapter.Enable(); err != nil {
-		return nil, fmt.Errorf("enable adapter: %w", err)
+	adapterOnce.Do(func() {
+		adapterErr = adapter.Enable()
+	})
+	if adapterErr != nil {
+		return nil, fmt.Errorf("enable adapter: %w", adapterErr)
 	}
 	return adapter, nil
 }
@@ -0,0 +1,37 @@
+package shellyrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// toParams marshals a typed params struct (as produced by shellyrpc/gen)
+// into the Params map Call expects.
+func toParams(v any) (Params, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params: %w", err)
+	}
+
+	params := Params{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("unmarshal params: %w", err)
+	}
+
+	return params, nil
+}
+
+// fromResult decodes a Result map into a typed result struct (as produced
+// by shellyrpc/gen).
+func fromResult(res Result, out any) error {
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("marshal result: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("unmarshal result: %w", err)
+	}
+
+	return nil
+}